@@ -0,0 +1,54 @@
+// Package broker defines typhon's pub/sub messaging abstraction. It is
+// independent of transport.Transport: Transport carries point-to-point
+// request/response RPC, while Broker lets services emit and consume
+// best-effort domain events (e.g. "user.created") on a named topic.
+package broker
+
+// Message is a pub/sub payload delivered to a Handler, independent of the
+// routing metadata (topic, queue group) used to deliver it.
+type Message struct {
+	Headers map[string]string
+	Body    []byte
+}
+
+// Handler processes a Message delivered by a Subscribe call. Returning a
+// non-nil error causes the delivery to be nacked (and, depending on the
+// backend, requeued); returning nil acks it.
+type Handler func(msg *Message) error
+
+// Subscriber represents an active Subscribe call.
+type Subscriber interface {
+	// Topic returns the topic this Subscriber was created for.
+	Topic() string
+	// Unsubscribe tears down the subscription; no further deliveries are
+	// made to its Handler once this returns.
+	Unsubscribe() error
+}
+
+// SubscribeOptions configures a Subscribe call.
+type SubscribeOptions struct {
+	// QueueName, when set, puts this subscriber into a named queue group:
+	// messages published to the topic are load-balanced across every
+	// subscriber sharing the same QueueName (competing consumers) instead
+	// of being fanned out to each of them.
+	QueueName string
+}
+
+// SubscribeOption mutates SubscribeOptions.
+type SubscribeOption func(*SubscribeOptions)
+
+// QueueName puts a Subscribe call into a named competing-consumer queue
+// group; see SubscribeOptions.QueueName.
+func QueueName(name string) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.QueueName = name
+	}
+}
+
+// Broker publishes and subscribes to topic-addressed pub/sub messages.
+type Broker interface {
+	Connect() error
+	Disconnect() error
+	Publish(topic string, msg *Message) error
+	Subscribe(topic string, handler Handler, opts ...SubscribeOption) (Subscriber, error)
+}
@@ -0,0 +1,222 @@
+// Package memtransport is an in-process transport.Transport implementation
+// that never touches a broker: Send hands requests directly to whatever
+// Listen call is registered for the target service within the same process.
+// It's registered as "memory" and is primarily useful for tests, where
+// spinning up RabbitMQ or NATS would be unnecessary overhead.
+package memtransport
+
+import (
+	"sync"
+	"time"
+
+	uuid "github.com/nu7hatch/gouuid"
+
+	"github.com/mondough/typhon/message"
+	"github.com/mondough/typhon/transport"
+)
+
+const (
+	acceptBacklog = 16 // buffered Sockets awaiting Accept
+	socketBuffer  = 64 // buffered frames per open Socket
+	acceptTimeout = 10 * time.Second
+)
+
+func init() {
+	transport.Register("memory", NewTransport)
+}
+
+type memTransport struct {
+	readyC chan struct{}
+
+	listenersM sync.RWMutex
+	listeners  map[string]chan<- message.Request
+
+	inflightM sync.Mutex
+	inflight  map[string]chan message.Response
+
+	acceptC chan transport.Socket
+}
+
+// NewTransport constructs a transport.Transport that delivers messages
+// in-process. opts are accepted for interface compatibility with other
+// backends but are unused: memtransport has no broker to address.
+func NewTransport(opts ...transport.Option) transport.Transport {
+	readyC := make(chan struct{})
+	close(readyC)
+	return &memTransport{
+		readyC:    readyC,
+		listeners: make(map[string]chan<- message.Request),
+		inflight:  make(map[string]chan message.Response),
+		acceptC:   make(chan transport.Socket, acceptBacklog),
+	}
+}
+
+func (t *memTransport) Ready() <-chan struct{} {
+	return t.readyC
+}
+
+func (t *memTransport) Listen(serviceName string, rc chan<- message.Request) error {
+	t.listenersM.Lock()
+	defer t.listenersM.Unlock()
+	if _, ok := t.listeners[serviceName]; ok {
+		return transport.ErrAlreadyListening
+	}
+	t.listeners[serviceName] = rc
+	return nil
+}
+
+func (t *memTransport) StopListening(serviceName string) bool {
+	t.listenersM.Lock()
+	defer t.listenersM.Unlock()
+	rc, ok := t.listeners[serviceName]
+	if !ok {
+		return false
+	}
+	delete(t.listeners, serviceName)
+	close(rc)
+	return true
+}
+
+// Send delivers req to whatever Listen call is registered for its service.
+// opts is accepted for interface compatibility but unused: there is no
+// broker round trip to retry, so a failed Send is already as cheap to retry
+// as the caller doing it themselves.
+func (t *memTransport) Send(req message.Request, timeout time.Duration, opts ...transport.CallOption) (message.Response, error) {
+	if req.Id() == "" {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return nil, err
+		}
+		req.SetId(id.String())
+	}
+
+	t.listenersM.RLock()
+	rc, ok := t.listeners[req.Service()]
+	t.listenersM.RUnlock()
+	if !ok {
+		return nil, transport.ErrTimeout
+	}
+
+	rspChan := make(chan message.Response, 1)
+	t.inflightM.Lock()
+	t.inflight[req.Id()] = rspChan
+	t.inflightM.Unlock()
+	defer func() {
+		t.inflightM.Lock()
+		delete(t.inflight, req.Id())
+		t.inflightM.Unlock()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case rc <- req:
+	case <-timer.C:
+		return nil, transport.ErrTimeout
+	}
+
+	select {
+	case rsp := <-rspChan:
+		return rsp, nil
+	case <-timer.C:
+		return nil, transport.ErrTimeout
+	}
+}
+
+func (t *memTransport) Respond(req message.Request, rsp message.Response) error {
+	rsp.SetId(req.Id())
+	t.inflightM.Lock()
+	rspChan, ok := t.inflight[req.Id()]
+	t.inflightM.Unlock()
+	if !ok {
+		return transport.ErrTimeout
+	}
+	rspChan <- rsp
+	return nil
+}
+
+// Dial opens a Socket to service. memtransport has no broker to route
+// through, so it just creates a pair of linked in-process Sockets (like
+// net.Pipe) and hands the peer's end to the next Accept call; service is
+// accepted as-is for interface compatibility but otherwise unused, since a
+// single Accept queue is shared by every service this transport Listens on.
+func (t *memTransport) Dial(service string) (transport.Socket, error) {
+	ab := make(chan *memFrame, socketBuffer)
+	ba := make(chan *memFrame, socketBuffer)
+	client := newMemSocket(ab, ba)
+	server := newMemSocket(ba, ab)
+
+	timer := time.NewTimer(acceptTimeout)
+	defer timer.Stop()
+	select {
+	case t.acceptC <- server:
+		return client, nil
+	case <-timer.C:
+		return nil, transport.ErrTimeout
+	}
+}
+
+// Accept blocks until a peer Dials a Socket against this transport.
+func (t *memTransport) Accept() (transport.Socket, error) {
+	return <-t.acceptC, nil
+}
+
+// memFrame is the in-process equivalent of a wire frame: enough of a
+// message.Message to reconstruct one on the receiving end, plus an end flag
+// used by Close to signal the peer with no further Sends possible.
+type memFrame struct {
+	id      string
+	headers map[string]string
+	payload []byte
+	end     bool
+}
+
+type memSocket struct {
+	send chan<- *memFrame
+	recv <-chan *memFrame
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newMemSocket(send chan<- *memFrame, recv <-chan *memFrame) *memSocket {
+	return &memSocket{send: send, recv: recv, closed: make(chan struct{})}
+}
+
+func (s *memSocket) Send(msg message.Message) error {
+	select {
+	case <-s.closed:
+		return transport.ErrSocketClosed
+	default:
+	}
+	select {
+	case s.send <- &memFrame{id: msg.Id(), headers: msg.Headers(), payload: msg.Payload()}:
+		return nil
+	case <-s.closed:
+		return transport.ErrSocketClosed
+	}
+}
+
+func (s *memSocket) Recv(msg message.Message) error {
+	select {
+	case f, ok := <-s.recv:
+		if !ok || f.end {
+			return transport.ErrSocketClosed
+		}
+		msg.SetId(f.id)
+		msg.SetHeaders(f.headers)
+		msg.SetPayload(f.payload)
+		return nil
+	case <-s.closed:
+		return transport.ErrSocketClosed
+	}
+}
+
+func (s *memSocket) Close() error {
+	s.closeOnce.Do(func() {
+		s.send <- &memFrame{end: true}
+		close(s.closed)
+	})
+	return nil
+}
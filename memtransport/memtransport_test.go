@@ -0,0 +1,61 @@
+package memtransport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mondough/typhon/message"
+	"github.com/mondough/typhon/transport"
+)
+
+func TestSendRespond(t *testing.T) {
+	tr := NewTransport()
+
+	rc := make(chan message.Request, 1)
+	if err := tr.Listen("echo", rc); err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer tr.StopListening("echo")
+
+	go func() {
+		req := <-rc
+		rsp := message.NewResponse()
+		rsp.SetPayload(req.Payload())
+		if err := tr.Respond(req, rsp); err != nil {
+			t.Errorf("Respond: %v", err)
+		}
+	}()
+
+	req := message.NewRequest()
+	req.SetService("echo")
+	req.SetPayload([]byte("ping"))
+
+	rsp, err := tr.Send(req, time.Second)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if string(rsp.Payload()) != "ping" {
+		t.Errorf("Send returned payload %q, want %q", rsp.Payload(), "ping")
+	}
+}
+
+func TestSendNoListener(t *testing.T) {
+	tr := NewTransport()
+	req := message.NewRequest()
+	req.SetService("nobody-listening")
+
+	if _, err := tr.Send(req, 10*time.Millisecond); err != transport.ErrTimeout {
+		t.Errorf("Send to an unregistered service returned %v, want %v", err, transport.ErrTimeout)
+	}
+}
+
+func TestListenTwiceForSameService(t *testing.T) {
+	tr := NewTransport()
+	rc := make(chan message.Request, 1)
+	if err := tr.Listen("dup", rc); err != nil {
+		t.Fatalf("first Listen: %v", err)
+	}
+	if err := tr.Listen("dup", rc); err != transport.ErrAlreadyListening {
+		t.Errorf("second Listen returned %v, want %v", err, transport.ErrAlreadyListening)
+	}
+}
@@ -0,0 +1,144 @@
+package memtransport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mondough/typhon/message"
+	"github.com/mondough/typhon/transport"
+)
+
+func TestDialAcceptStream(t *testing.T) {
+	tr := NewTransport()
+
+	acceptErr := make(chan error, 1)
+	var server transport.Socket
+	go func() {
+		var err error
+		server, err = tr.Accept()
+		acceptErr <- err
+	}()
+
+	client, err := tr.Dial("echo")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	if err := <-acceptErr; err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+
+	sent := message.NewRequest()
+	sent.SetPayload([]byte("frame-1"))
+	if err := client.Send(sent); err != nil {
+		t.Fatalf("client.Send: %v", err)
+	}
+
+	got := message.NewRequest()
+	if err := server.Recv(got); err != nil {
+		t.Fatalf("server.Recv: %v", err)
+	}
+	if string(got.Payload()) != "frame-1" {
+		t.Errorf("server.Recv got payload %q, want %q", got.Payload(), "frame-1")
+	}
+}
+
+// TestSocketCloseSignalsEnd exercises the Close/stream-end path: once one
+// end closes, the peer's Recv must return transport.ErrSocketClosed instead
+// of delivering the terminal frame as an ordinary message.
+func TestSocketCloseSignalsEnd(t *testing.T) {
+	tr := NewTransport()
+
+	serverC := make(chan transport.Socket, 1)
+	go func() {
+		sock, err := tr.Accept()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		serverC <- sock
+	}()
+
+	client, err := tr.Dial("echo")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	server := <-serverC
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("client.Close: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Recv(message.NewRequest())
+	}()
+
+	select {
+	case err := <-done:
+		if err != transport.ErrSocketClosed {
+			t.Errorf("server.Recv after peer Close returned %v, want %v", err, transport.ErrSocketClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("server.Recv did not return after peer Close")
+	}
+
+	if err := client.Send(message.NewRequest()); err != transport.ErrSocketClosed {
+		t.Errorf("Send on a closed socket returned %v, want %v", err, transport.ErrSocketClosed)
+	}
+}
+
+// TestSocketCloseDeliversEndFrameWhenBufferFull guards against a regression
+// where Close's terminal-frame send used select/default, silently dropping
+// the frame (instead of waiting for room) whenever the peer hadn't yet
+// drained a full socketBuffer of ordinary frames — leaving the peer's next
+// Recv blocked forever with nothing left to ever signal it.
+func TestSocketCloseDeliversEndFrameWhenBufferFull(t *testing.T) {
+	tr := NewTransport()
+
+	serverC := make(chan transport.Socket, 1)
+	go func() {
+		sock, err := tr.Accept()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		serverC <- sock
+	}()
+
+	client, err := tr.Dial("echo")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	server := <-serverC
+
+	for i := 0; i < socketBuffer; i++ {
+		msg := message.NewRequest()
+		msg.SetPayload([]byte("frame"))
+		if err := client.Send(msg); err != nil {
+			t.Fatalf("client.Send (filling buffer): %v", err)
+		}
+	}
+
+	closeErr := make(chan error, 1)
+	go func() { closeErr <- client.Close() }()
+
+	for i := 0; i < socketBuffer; i++ {
+		if err := server.Recv(message.NewRequest()); err != nil {
+			t.Fatalf("server.Recv frame %d: %v", i, err)
+		}
+	}
+
+	select {
+	case err := <-closeErr:
+		if err != nil {
+			t.Fatalf("client.Close: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("client.Close did not return once the buffer drained")
+	}
+
+	if err := server.Recv(message.NewRequest()); err != transport.ErrSocketClosed {
+		t.Errorf("server.Recv after the buffer drained and peer closed returned %v, want %v",
+			err, transport.ErrSocketClosed)
+	}
+}
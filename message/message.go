@@ -0,0 +1,113 @@
+// Package message defines the wire-agnostic Request/Response types exchanged
+// across a typhon transport.Transport.
+package message
+
+// Message is the common behaviour shared by every typhon Request and
+// Response: an identifier used to correlate requests with their responses,
+// a set of string headers, and an opaque payload.
+type Message interface {
+	Id() string
+	SetId(id string)
+
+	Headers() map[string]string
+	SetHeaders(headers map[string]string)
+	SetHeader(key, value string)
+
+	Payload() []byte
+	SetPayload(payload []byte)
+}
+
+// Request is a Message sent from a client to a service endpoint.
+type Request interface {
+	Message
+
+	Service() string
+	SetService(service string)
+
+	Endpoint() string
+	SetEndpoint(endpoint string)
+}
+
+// Response is a Message sent back in reply to a Request.
+type Response interface {
+	Message
+}
+
+type message struct {
+	id      string
+	headers map[string]string
+	payload []byte
+}
+
+func (m *message) Id() string {
+	return m.id
+}
+
+func (m *message) SetId(id string) {
+	m.id = id
+}
+
+func (m *message) Headers() map[string]string {
+	return m.headers
+}
+
+func (m *message) SetHeaders(headers map[string]string) {
+	m.headers = headers
+}
+
+func (m *message) SetHeader(key, value string) {
+	if m.headers == nil {
+		m.headers = make(map[string]string)
+	}
+	m.headers[key] = value
+}
+
+func (m *message) Payload() []byte {
+	return m.payload
+}
+
+func (m *message) SetPayload(payload []byte) {
+	m.payload = payload
+}
+
+type request struct {
+	message
+	service  string
+	endpoint string
+}
+
+// NewRequest builds an empty Request ready to have its headers, payload and
+// routing information populated.
+func NewRequest() Request {
+	return &request{
+		message: message{headers: make(map[string]string)},
+	}
+}
+
+func (r *request) Service() string {
+	return r.service
+}
+
+func (r *request) SetService(service string) {
+	r.service = service
+}
+
+func (r *request) Endpoint() string {
+	return r.endpoint
+}
+
+func (r *request) SetEndpoint(endpoint string) {
+	r.endpoint = endpoint
+}
+
+type response struct {
+	message
+}
+
+// NewResponse builds an empty Response ready to have its headers and payload
+// populated.
+func NewResponse() Response {
+	return &response{
+		message: message{headers: make(map[string]string)},
+	}
+}
@@ -0,0 +1,206 @@
+package nats
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	log "github.com/mondough/slog"
+	natsio "github.com/nats-io/nats"
+	"golang.org/x/net/context"
+
+	"github.com/mondough/typhon/message"
+	"github.com/mondough/typhon/transport"
+)
+
+const (
+	streamOpenHeader = "X-Stream-Open"
+	streamEndHeader  = "X-Stream-End"
+	seqHeader        = "X-Seq"
+)
+
+// Dial opens a Socket to service. A fresh inbox subject is created for the
+// life of the Socket: the handshake (and every subsequent Send) is published
+// to service with that inbox as the NATS reply subject, and frames the peer
+// sends back arrive on it directly.
+func (t *natsTransport) Dial(service string) (transport.Socket, error) {
+	<-t.Ready()
+
+	inbox := natsio.NewInbox()
+	frames := make(chan *natsio.Msg, socketBuffer)
+	sock := newNatsSocket(frames, func(data []byte) error {
+		return t.connection().PublishRequest(service, inbox, data)
+	})
+
+	sub, err := t.connection().Subscribe(inbox, func(msg *natsio.Msg) {
+		select {
+		case frames <- msg:
+		case <-sock.closed:
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sock.sub = sub
+
+	data, err := json.Marshal(wireMessage{Headers: map[string]string{streamOpenHeader: "true"}})
+	if err != nil {
+		sub.Unsubscribe()
+		return nil, err
+	}
+	if err := t.connection().PublishRequest(service, inbox, data); err != nil {
+		sub.Unsubscribe()
+		return nil, err
+	}
+	return sock, nil
+}
+
+// Accept blocks until a peer Dials a Socket against a service this transport
+// is Listen-ing on.
+func (t *natsTransport) Accept() (transport.Socket, error) {
+	return <-t.acceptC, nil
+}
+
+// routeStreamFrame handles a delivery arriving on a service subject that
+// belongs to the Socket stream protocol rather than the plain request path:
+// either a frame for an already-accepted Socket (keyed by the dialer's reply
+// subject) or the handshake opening a new one. It returns true if msg was a
+// stream frame and has been fully handled.
+func (t *natsTransport) routeStreamFrame(msg *natsio.Msg, wire wireMessage) bool {
+	ctx := context.Background()
+
+	t.socketsM.Lock()
+	frames, ok := t.sockets[msg.Reply]
+	t.socketsM.Unlock()
+	if ok {
+		select {
+		case frames <- msg:
+		default:
+			log.Error(ctx, "[Typhon:NatsTransport] Could not deliver stream frame from %s: socket buffer full", msg.Reply)
+		}
+		return true
+	}
+
+	if wire.Headers[streamOpenHeader] != "true" {
+		return false
+	}
+
+	frames = make(chan *natsio.Msg, socketBuffer)
+	t.socketsM.Lock()
+	t.sockets[msg.Reply] = frames
+	t.socketsM.Unlock()
+
+	replyTo := msg.Reply
+	sock := newNatsSocket(frames, func(data []byte) error {
+		return t.connection().Publish(replyTo, data)
+	})
+	sock.onClose = func() {
+		t.socketsM.Lock()
+		delete(t.sockets, replyTo)
+		t.socketsM.Unlock()
+	}
+
+	select {
+	case t.acceptC <- sock:
+	default:
+		log.Warn(ctx, "[Typhon:NatsTransport] Accept queue full; dropping socket dialed from %s", replyTo)
+	}
+	return true
+}
+
+// natsSocket is a transport.Socket backed by a stream of NATS messages on a
+// dedicated subject. publish sends one frame's worth of data to the peer;
+// the constructor fixes whether that's a PublishRequest to a service (client
+// side) or a plain Publish to the dialer's inbox (server/accepted side).
+type natsSocket struct {
+	frames  chan *natsio.Msg
+	publish func(data []byte) error
+	sub     *natsio.Subscription
+	onClose func()
+
+	seqM sync.Mutex
+	seq  uint64
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newNatsSocket(frames chan *natsio.Msg, publish func([]byte) error) *natsSocket {
+	return &natsSocket{frames: frames, publish: publish, closed: make(chan struct{})}
+}
+
+func (s *natsSocket) Send(msg message.Message) error {
+	select {
+	case <-s.closed:
+		return transport.ErrSocketClosed
+	default:
+	}
+
+	headers := msg.Headers()
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	s.seqM.Lock()
+	seq := s.seq
+	s.seq++
+	s.seqM.Unlock()
+	headers[seqHeader] = strconv.FormatUint(seq, 10)
+
+	data, err := json.Marshal(wireMessage{Id: msg.Id(), Headers: headers, Payload: msg.Payload()})
+	if err != nil {
+		return err
+	}
+	return s.publish(data)
+}
+
+func (s *natsSocket) Recv(msg message.Message) error {
+	select {
+	case raw, ok := <-s.frames:
+		if !ok {
+			return transport.ErrSocketClosed
+		}
+		var wire wireMessage
+		if err := json.Unmarshal(raw.Data, &wire); err != nil {
+			return err
+		}
+		if wire.Headers[streamEndHeader] == "true" {
+			s.Close()
+			return transport.ErrSocketClosed
+		}
+		msg.SetId(wire.Id)
+		msg.SetHeaders(wire.Headers)
+		msg.SetPayload(wire.Payload)
+		return nil
+	case <-s.closed:
+		return transport.ErrSocketClosed
+	}
+}
+
+func (s *natsSocket) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		data, merr := json.Marshal(wireMessage{Headers: map[string]string{streamEndHeader: "true"}})
+		if merr == nil {
+			err = s.publish(data)
+		} else {
+			err = merr
+		}
+		close(s.closed)
+		if s.sub != nil {
+			s.sub.Unsubscribe()
+		}
+		if s.onClose != nil {
+			s.onClose()
+		}
+
+	drain:
+		for {
+			select {
+			case <-s.frames:
+			default:
+				break drain
+			}
+		}
+	})
+	return err
+}
@@ -0,0 +1,318 @@
+// Package nats is a transport.Transport implementation backed by NATS. It
+// maps typhon's Service/Endpoint routing onto NATS subjects (Send publishes
+// to the subject named after req.Service()) and uses a single per-transport
+// inbox subject for replies, with the AMQP correlation id pattern from
+// rabbitTransport re-used to match responses back to their Send call.
+package nats
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/mondough/slog"
+	natsio "github.com/nats-io/nats"
+	uuid "github.com/nu7hatch/gouuid"
+	"golang.org/x/net/context"
+
+	"github.com/mondough/typhon/message"
+	"github.com/mondough/typhon/transport"
+)
+
+const (
+	connectTimeout  = 60 * time.Second
+	chanSendTimeout = 10 * time.Second
+	acceptBacklog   = 16 // buffered Sockets awaiting Accept
+	socketBuffer    = 64 // buffered frames per open Socket
+
+	replyToHeader = "X-Nats-ReplyTo"
+)
+
+func init() {
+	transport.Register("nats", NewTransport)
+}
+
+// wireMessage is the on-the-wire representation of a typhon message.Message.
+// NATS carries opaque bytes with no header support of its own, so headers
+// and the routing/payload fields are encoded together to survive the trip.
+type wireMessage struct {
+	Id       string            `json:"id"`
+	Service  string            `json:"service,omitempty"`
+	Endpoint string            `json:"endpoint,omitempty"`
+	Headers  map[string]string `json:"headers"`
+	Payload  []byte            `json:"payload"`
+}
+
+type natsTransport struct {
+	addrs string
+
+	connM sync.RWMutex
+	conn  *natsio.Conn
+
+	readyC     chan struct{}
+	readyOnce  sync.Once
+	replyInbox string
+
+	inflightM sync.Mutex
+	inflight  map[string]chan message.Response
+
+	listenersM sync.RWMutex
+	listeners  map[string]*natsio.Subscription
+
+	socketsM sync.Mutex
+	sockets  map[string]chan *natsio.Msg // client inbox subject: stream frame chan, for open Sockets
+	acceptC  chan transport.Socket       // Sockets opened by a peer, awaiting Accept
+}
+
+// NewTransport constructs a transport.Transport backed by NATS. The
+// transport.Addresses option selects which NATS server(s) to connect to;
+// if unset, the client library's default ("nats://127.0.0.1:4222") is used.
+func NewTransport(opts ...transport.Option) transport.Transport {
+	var options transport.Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	t := &natsTransport{
+		replyInbox: natsio.NewInbox(),
+		readyC:     make(chan struct{}),
+		inflight:   make(map[string]chan message.Response),
+		listeners:  make(map[string]*natsio.Subscription),
+		sockets:    make(map[string]chan *natsio.Msg),
+		acceptC:    make(chan transport.Socket, acceptBacklog),
+	}
+	if len(options.Addresses) > 0 {
+		t.addrs = joinAddresses(options.Addresses)
+	}
+	go t.connect()
+	return t
+}
+
+func joinAddresses(addrs []string) string {
+	out := addrs[0]
+	for _, a := range addrs[1:] {
+		out += "," + a
+	}
+	return out
+}
+
+func (t *natsTransport) connect() {
+	ctx := context.Background()
+
+	opts := natsio.DefaultOptions
+	if t.addrs != "" {
+		opts.Servers = strings.Split(t.addrs, ",")
+	}
+
+	timeout := time.NewTimer(connectTimeout)
+	defer timeout.Stop()
+
+	conn, err := opts.Connect()
+	if err != nil {
+		log.Critical(ctx, "[Typhon:NatsTransport] Failed to connect to NATS: %v", err)
+		return
+	}
+
+	sub, err := conn.Subscribe(t.replyInbox, t.handleRspDelivery)
+	if err != nil {
+		log.Critical(ctx, "[Typhon:NatsTransport] Failed to subscribe to reply inbox %s: %v", t.replyInbox, err)
+		conn.Close()
+		return
+	}
+	sub.SetPendingLimits(-1, -1)
+
+	t.connM.Lock()
+	t.conn = conn
+	t.connM.Unlock()
+
+	log.Info(ctx, "[Typhon:NatsTransport] Connected; listening for replies on %s", t.replyInbox)
+	t.readyOnce.Do(func() { close(t.readyC) })
+}
+
+func (t *natsTransport) connection() *natsio.Conn {
+	t.connM.RLock()
+	defer t.connM.RUnlock()
+	return t.conn
+}
+
+func (t *natsTransport) Ready() <-chan struct{} {
+	return t.readyC
+}
+
+func (t *natsTransport) Listen(serviceName string, rc chan<- message.Request) error {
+	t.listenersM.Lock()
+	defer t.listenersM.Unlock()
+	if _, ok := t.listeners[serviceName]; ok {
+		return transport.ErrAlreadyListening
+	}
+
+	timeout := time.NewTimer(connectTimeout)
+	defer timeout.Stop()
+	select {
+	case <-t.Ready():
+	case <-timeout.C:
+		return transport.ErrTimeout
+	}
+
+	sub, err := t.connection().Subscribe(serviceName, func(msg *natsio.Msg) {
+		t.handleReqDelivery(msg, rc)
+	})
+	if err != nil {
+		return err
+	}
+	t.listeners[serviceName] = sub
+	return nil
+}
+
+func (t *natsTransport) StopListening(serviceName string) bool {
+	t.listenersM.Lock()
+	defer t.listenersM.Unlock()
+	sub, ok := t.listeners[serviceName]
+	if !ok {
+		return false
+	}
+	delete(t.listeners, serviceName)
+	sub.Unsubscribe()
+	return true
+}
+
+// Send publishes req to its service subject and waits for a matching reply.
+// opts is accepted for interface compatibility but unused: NATS itself has
+// no durable queue to retry against, so retrying here would just republish
+// against the same at-most-once subject the caller could republish to
+// directly.
+func (t *natsTransport) Send(req message.Request, _timeout time.Duration, opts ...transport.CallOption) (message.Response, error) {
+	ctx := context.Background()
+	if req.Id() == "" {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return nil, err
+		}
+		req.SetId(id.String())
+	}
+
+	rspChan := make(chan message.Response, 1)
+	t.inflightM.Lock()
+	t.inflight[req.Id()] = rspChan
+	t.inflightM.Unlock()
+	defer func() {
+		t.inflightM.Lock()
+		delete(t.inflight, req.Id())
+		t.inflightM.Unlock()
+	}()
+
+	timeout := time.NewTimer(_timeout)
+	defer timeout.Stop()
+
+	select {
+	case <-t.Ready():
+	case <-timeout.C:
+		return nil, transport.ErrTimeout
+	}
+
+	headers := req.Headers()
+	headers[replyToHeader] = t.replyInbox
+	data, err := json.Marshal(wireMessage{
+		Id:       req.Id(),
+		Service:  req.Service(),
+		Endpoint: req.Endpoint(),
+		Headers:  headers,
+		Payload:  req.Payload(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.connection().PublishRequest(req.Service(), t.replyInbox, data); err != nil {
+		log.Error(ctx, "[Typhon:NatsTransport] Failed to publish to %s: %v", req.Service(), err)
+		return nil, err
+	}
+
+	select {
+	case rsp := <-rspChan:
+		return rsp, nil
+	case <-timeout.C:
+		log.Warn(ctx, "[Typhon:NatsTransport] Timed out after %v waiting for response to %s", _timeout, req.Id())
+		return nil, transport.ErrTimeout
+	}
+}
+
+func (t *natsTransport) Respond(req message.Request, rsp message.Response) error {
+	replyTo := req.Headers()[replyToHeader]
+	if replyTo == "" {
+		return transport.ErrTimeout
+	}
+
+	data, err := json.Marshal(wireMessage{
+		Id:      rsp.Id(),
+		Headers: rsp.Headers(),
+		Payload: rsp.Payload(),
+	})
+	if err != nil {
+		return err
+	}
+	return t.connection().Publish(replyTo, data)
+}
+
+func (t *natsTransport) handleReqDelivery(msg *natsio.Msg, rc chan<- message.Request) {
+	ctx := context.Background()
+	var wire wireMessage
+	if err := json.Unmarshal(msg.Data, &wire); err != nil {
+		log.Error(ctx, "[Typhon:NatsTransport] Could not decode request on %s: %v", msg.Subject, err)
+		return
+	}
+
+	if t.routeStreamFrame(msg, wire) {
+		return
+	}
+
+	req := message.NewRequest()
+	req.SetId(wire.Id)
+	req.SetHeaders(wire.Headers)
+	req.SetHeader(replyToHeader, msg.Reply)
+	req.SetPayload(wire.Payload)
+	req.SetService(wire.Service)
+	req.SetEndpoint(wire.Endpoint)
+
+	timeout := time.NewTimer(chanSendTimeout)
+	defer timeout.Stop()
+	select {
+	case rc <- req:
+	case <-timeout.C:
+		log.Error(ctx, "[Typhon:NatsTransport] Could not deliver request %s after %v: receiving channel is full",
+			wire.Id, chanSendTimeout)
+	}
+}
+
+func (t *natsTransport) handleRspDelivery(msg *natsio.Msg) {
+	ctx := context.Background()
+	var wire wireMessage
+	if err := json.Unmarshal(msg.Data, &wire); err != nil {
+		log.Error(ctx, "[Typhon:NatsTransport] Could not decode response on %s: %v", msg.Subject, err)
+		return
+	}
+
+	t.inflightM.Lock()
+	rspChan, ok := t.inflight[wire.Id]
+	delete(t.inflight, wire.Id)
+	t.inflightM.Unlock()
+	if !ok {
+		log.Warn(ctx, "[Typhon:NatsTransport] Could not match response %s to an inflight request", wire.Id)
+		return
+	}
+
+	rsp := message.NewResponse()
+	rsp.SetId(wire.Id)
+	rsp.SetHeaders(wire.Headers)
+	rsp.SetPayload(wire.Payload)
+
+	timeout := time.NewTimer(chanSendTimeout)
+	defer timeout.Stop()
+	select {
+	case rspChan <- rsp:
+	case <-timeout.C:
+		log.Error(ctx, "[Typhon:NatsTransport] Could not deliver response %s after %v: receiving channel is full",
+			wire.Id, chanSendTimeout)
+	}
+}
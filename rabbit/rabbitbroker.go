@@ -0,0 +1,155 @@
+package rabbit
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	log "github.com/mondough/slog"
+	uuid "github.com/nu7hatch/gouuid"
+	"github.com/streadway/amqp"
+	"golang.org/x/net/context"
+	"gopkg.in/tomb.v2"
+
+	"github.com/mondough/typhon/broker"
+	"github.com/mondough/typhon/transport"
+)
+
+// BrokerExchange is the topic exchange that all rabbitBroker pub/sub traffic
+// is declared and bound against.
+const BrokerExchange = "typhon.broker"
+
+type rabbitBroker struct {
+	transport *rabbitTransport
+
+	subsM sync.Mutex
+	subs  map[string]*rabbitSubscriber
+}
+
+// ErrNotRabbitTransport is returned by NewBroker when rt was not
+// constructed by this package's NewTransport.
+var ErrNotRabbitTransport = errors.New("rabbit.NewBroker: transport was not constructed by rabbit.NewTransport")
+
+// NewBroker constructs a broker.Broker that publishes and subscribes over
+// the same RabbitConnection as rt, so a service that uses both RPC and
+// pub/sub still only opens a single AMQP connection. rt must have been
+// constructed by this package's NewTransport; passing any other
+// transport.Transport (e.g. one backed by nats or memtransport) returns
+// ErrNotRabbitTransport rather than panicking.
+func NewBroker(rt transport.Transport) (broker.Broker, error) {
+	t, ok := rt.(*rabbitTransport)
+	if !ok {
+		return nil, ErrNotRabbitTransport
+	}
+	return &rabbitBroker{
+		transport: t,
+		subs:      make(map[string]*rabbitSubscriber),
+	}, nil
+}
+
+func (b *rabbitBroker) Connect() error {
+	<-b.transport.Ready()
+	return b.transport.connection().Channel.DeclareTopicExchange(BrokerExchange)
+}
+
+func (b *rabbitBroker) Disconnect() error {
+	b.subsM.Lock()
+	defer b.subsM.Unlock()
+	for subId, sub := range b.subs {
+		sub.Unsubscribe()
+		delete(b.subs, subId)
+	}
+	return nil
+}
+
+func (b *rabbitBroker) Publish(topic string, msg *broker.Message) error {
+	return b.transport.connection().Publish(BrokerExchange, topic, amqp.Publishing{
+		Headers: headersToTable(msg.Headers),
+		Body:    msg.Body,
+	})
+}
+
+// Subscribe binds an auto-delete queue to topic on BrokerExchange and
+// dispatches deliveries to handler on their own goroutine, acking on a nil
+// return and nacking (with requeue) otherwise. With no QueueName every
+// subscriber gets its own exclusive queue so all of them see every message;
+// subscribers sharing a QueueName compete for deliveries instead.
+func (b *rabbitBroker) Subscribe(topic string, handler broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	var options broker.SubscribeOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	conn := b.transport.connection()
+
+	subId, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+
+	queueName := options.QueueName
+	exclusive := queueName == ""
+	if exclusive {
+		queueName = fmt.Sprintf("%s.%s", topic, subId.String())
+	}
+	if err := conn.Channel.DeclareBoundQueue(queueName, topic, BrokerExchange, exclusive); err != nil {
+		return nil, err
+	}
+
+	deliveries, rabbitChannel, err := conn.Consume(queueName)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &rabbitSubscriber{
+		topic:   topic,
+		channel: rabbitChannel,
+		tomb:    new(tomb.Tomb),
+	}
+	sub.tomb.Go(func() error {
+		for delivery := range deliveries {
+			d := delivery
+			go b.handleDelivery(topic, d, handler)
+		}
+		return nil
+	})
+
+	// Keyed by a per-Subscribe-call id rather than queueName: multiple
+	// Subscribe calls sharing a QueueName (the documented competing-
+	// consumers case) are otherwise indistinguishable, and would overwrite
+	// one another's entry so Disconnect only tears down the last of them.
+	b.subsM.Lock()
+	b.subs[subId.String()] = sub
+	b.subsM.Unlock()
+	return sub, nil
+}
+
+func (b *rabbitBroker) handleDelivery(topic string, delivery amqp.Delivery, handler broker.Handler) {
+	ctx := context.Background()
+	msg := &broker.Message{
+		Headers: tableToHeaders(delivery.Headers),
+		Body:    delivery.Body,
+	}
+	if err := handler(msg); err != nil {
+		log.Warn(ctx, "[Typhon:RabbitBroker] Handler for %s returned error, nacking: %v", topic, err)
+		delivery.Nack(false, true)
+		return
+	}
+	delivery.Ack(false)
+}
+
+type rabbitSubscriber struct {
+	topic   string
+	channel *amqp.Channel
+	tomb    *tomb.Tomb
+}
+
+func (s *rabbitSubscriber) Topic() string {
+	return s.topic
+}
+
+func (s *rabbitSubscriber) Unsubscribe() error {
+	err := s.channel.Close()
+	s.tomb.Wait()
+	return err
+}
@@ -0,0 +1,252 @@
+package rabbit
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/mondough/slog"
+	uuid "github.com/nu7hatch/gouuid"
+	"github.com/streadway/amqp"
+	"golang.org/x/net/context"
+	"gopkg.in/tomb.v2"
+
+	"github.com/mondough/typhon/message"
+	"github.com/mondough/typhon/transport"
+)
+
+const (
+	streamOpenHeader = "X-Stream-Open"
+	streamEndHeader  = "X-Stream-End"
+	seqHeader        = "X-Seq"
+)
+
+// Dial opens a Socket to service: a stream of messages sharing a single
+// correlation id, delivered to whichever consumer on that service calls
+// Accept. Frames are published with Content-Encoding "stream" and the
+// shared CorrelationId, stamped with a monotonic X-Seq; replies are
+// delivered to the transport's own reply queue, the same inbox Send uses.
+func (t *rabbitTransport) Dial(service string) (transport.Socket, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	cid := id.String()
+
+	frames := make(chan amqp.Delivery, socketBuffer)
+	t.socketsM.Lock()
+	t.sockets[cid] = frames
+	t.socketsM.Unlock()
+
+	timeout := time.NewTimer(connectTimeout)
+	defer timeout.Stop()
+	select {
+	case <-t.Ready():
+	case <-t.Dying():
+		return nil, tomb.ErrDying
+	case <-timeout.C:
+		return nil, transport.ErrTimeout
+	}
+
+	sock := newRabbitSocket(t, cid, frames, func(body []byte, headers amqp.Table) error {
+		return t.connection().Publish(Exchange, service, amqp.Publishing{
+			CorrelationId: cid,
+			Timestamp:     time.Now().UTC(),
+			Body:          body,
+			ReplyTo:       t.replyQueue,
+			Headers:       headers,
+		})
+	})
+
+	if err := sock.publish(nil, headersToTable(map[string]string{
+		"Content-Encoding": "stream",
+		streamOpenHeader:   "true",
+	})); err != nil {
+		t.socketsM.Lock()
+		delete(t.sockets, cid)
+		t.socketsM.Unlock()
+		return nil, err
+	}
+	return sock, nil
+}
+
+// Accept blocks until a peer Dials a Socket against a service this transport
+// is Listen-ing on.
+func (t *rabbitTransport) Accept() (transport.Socket, error) {
+	select {
+	case sock := <-t.acceptC:
+		return sock, nil
+	case <-t.Dying():
+		return nil, tomb.ErrDying
+	}
+}
+
+// handleStreamOpenOrForward handles a "stream" delivery received on a
+// service queue (i.e. arriving via a Listen consumer). The first frame for a
+// given correlation id carries X-Stream-Open and creates a new accepted
+// Socket; subsequent frames are forwarded to the Socket already created for
+// that correlation id.
+func (t *rabbitTransport) handleStreamOpenOrForward(delivery amqp.Delivery) {
+	ctx := context.Background()
+	cid := delivery.CorrelationId
+
+	t.socketsM.Lock()
+	frames, ok := t.sockets[cid]
+	if !ok {
+		frames = make(chan amqp.Delivery, socketBuffer)
+		t.sockets[cid] = frames
+	}
+	t.socketsM.Unlock()
+
+	if !ok {
+		replyTo := delivery.ReplyTo
+		sock := newRabbitSocket(t, cid, frames, func(body []byte, headers amqp.Table) error {
+			return t.connection().Publish("", replyTo, amqp.Publishing{
+				CorrelationId: cid,
+				Timestamp:     time.Now().UTC(),
+				Body:          body,
+				Headers:       headers,
+			})
+		})
+		timeout := time.NewTimer(chanSendTimeout)
+		defer timeout.Stop()
+		select {
+		case t.acceptC <- sock:
+		case <-t.Dying():
+		case <-timeout.C:
+			atomic.AddUint64(&t.dropCount, 1)
+			log.Error(ctx, "[Typhon:RabbitTransport] Dropped stream open %s after %v: nobody is Accept-ing",
+				t.logId(delivery), chanSendTimeout)
+			t.socketsM.Lock()
+			delete(t.sockets, cid)
+			t.socketsM.Unlock()
+		}
+		return // the handshake frame itself carries no payload
+	}
+
+	t.deliverStreamFrame(ctx, frames, delivery)
+}
+
+// handleStreamForward handles a "stream" delivery received on the
+// transport's reply queue (i.e. a response to a Socket this transport
+// Dialed). The Socket must already be registered in t.sockets.
+func (t *rabbitTransport) handleStreamForward(delivery amqp.Delivery) {
+	ctx := context.Background()
+	t.socketsM.Lock()
+	frames, ok := t.sockets[delivery.CorrelationId]
+	t.socketsM.Unlock()
+	if !ok {
+		log.Warn(ctx, "[Typhon:RabbitTransport] Could not match stream frame %s to a socket", t.logId(delivery))
+		return
+	}
+	t.deliverStreamFrame(ctx, frames, delivery)
+}
+
+func (t *rabbitTransport) deliverStreamFrame(ctx context.Context, frames chan amqp.Delivery, delivery amqp.Delivery) {
+	timeout := time.NewTimer(chanSendTimeout)
+	defer timeout.Stop()
+	select {
+	case frames <- delivery:
+	case <-timeout.C:
+		log.Error(ctx, "[Typhon:RabbitTransport] Could not deliver stream frame %s after %v: socket buffer full",
+			t.logId(delivery), chanSendTimeout)
+	}
+}
+
+// rabbitSocket is a transport.Socket backed by a stream of AMQP deliveries
+// sharing a single CorrelationId. publish sends one frame's worth of body
+// and headers to the peer, with the exchange/routing-key (client: Exchange
+// + service name; server: default exchange + the peer's ReplyTo) fixed by
+// the constructor.
+type rabbitSocket struct {
+	t       *rabbitTransport
+	cid     string
+	frames  chan amqp.Delivery
+	publish func(body []byte, headers amqp.Table) error
+
+	seqM sync.Mutex
+	seq  uint64
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newRabbitSocket(t *rabbitTransport, cid string, frames chan amqp.Delivery, publish func([]byte, amqp.Table) error) *rabbitSocket {
+	return &rabbitSocket{
+		t:       t,
+		cid:     cid,
+		frames:  frames,
+		publish: publish,
+		closed:  make(chan struct{}),
+	}
+}
+
+func (s *rabbitSocket) Send(msg message.Message) error {
+	select {
+	case <-s.closed:
+		return transport.ErrSocketClosed
+	default:
+	}
+
+	headers := msg.Headers()
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	headers["Content-Encoding"] = "stream"
+
+	s.seqM.Lock()
+	seq := s.seq
+	s.seq++
+	s.seqM.Unlock()
+	headers[seqHeader] = strconv.FormatUint(seq, 10)
+
+	return s.publish(msg.Payload(), headersToTable(headers))
+}
+
+func (s *rabbitSocket) Recv(msg message.Message) error {
+	select {
+	case delivery, ok := <-s.frames:
+		if !ok {
+			return transport.ErrSocketClosed
+		}
+		if tableToHeaders(delivery.Headers)[streamEndHeader] == "true" {
+			s.Close()
+			return transport.ErrSocketClosed
+		}
+		msg.SetId(s.cid)
+		msg.SetHeaders(tableToHeaders(delivery.Headers))
+		msg.SetPayload(delivery.Body)
+		return nil
+	case <-s.closed:
+		return transport.ErrSocketClosed
+	}
+}
+
+func (s *rabbitSocket) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		err = s.publish(nil, headersToTable(map[string]string{
+			"Content-Encoding": "stream",
+			streamEndHeader:    "true",
+		}))
+		close(s.closed)
+
+		s.t.socketsM.Lock()
+		delete(s.t.sockets, s.cid)
+		s.t.socketsM.Unlock()
+
+		// Drain whatever frames were already buffered so a concurrent
+		// deliverStreamFrame doesn't block forever on a socket nobody is
+		// reading from any more.
+	drain:
+		for {
+			select {
+			case <-s.frames:
+			default:
+				break drain
+			}
+		}
+	})
+	return err
+}
@@ -3,7 +3,9 @@ package rabbit
 import (
 	"fmt"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/mondough/slog"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/mondough/terrors"
 	"github.com/mondough/typhon/message"
+	"github.com/mondough/typhon/service"
 	"github.com/mondough/typhon/transport"
 )
 
@@ -22,25 +25,51 @@ const (
 	connectTimeout   = 60 * time.Second
 	chanSendTimeout  = 10 * time.Second
 	respondTimeout   = 10 * time.Second
+	acceptBacklog    = 16 // buffered Sockets awaiting Accept
+	socketBuffer     = 64 // buffered frames per open Socket
+
+	defaultMaxInflight    = 4096 // default cap on outstanding Send calls
+	defaultWorkerPoolSize = 32   // default number of delivery-handling goroutines
+	workQueueSize         = 1024 // buffered deliveries awaiting a free worker
 )
 
 var (
 	ErrCouldntConnect   = terrors.InternalService("", "Could not connect to RabbitMQ", nil)
 	ErrDeliveriesClosed = terrors.InternalService("", "Delivery channel closed", nil)
 	ErrNoReplyTo        = terrors.BadRequest("", "Request does not have appropriate X-Rabbit-ReplyTo header", nil)
+	ErrQueueFull        = terrors.InternalService("", "Too many in-flight requests", nil)
 )
 
 type rabbitTransport struct {
-	tomb          *tomb.Tomb
-	connM         sync.RWMutex                       // protects conn + connReady
-	conn          *RabbitConnection                  // underlying connection
-	connReady     chan struct{}                      // swapped along with conn (reconnecting)
-	replyQueue    string                             // message reply queue name
-	inflightReqs  map[string]chan<- message.Response // correlation id: response chan
-	inflightReqsM sync.Mutex                         // protects inflightReqs
-	listeners     map[string]*tomb.Tomb              // service name: tomb
-	listenersM    sync.RWMutex                       // protects listeners
-	runOnce       sync.Once                          // kicks off the run loop
+	service.BaseService
+	connM          sync.RWMutex                       // protects conn + connReady
+	conn           *RabbitConnection                  // underlying connection
+	connReady      chan struct{}                      // swapped along with conn (reconnecting)
+	connectedOnce  bool                               // true once the first successful connect has happened
+	reconnectC     chan struct{}                      // closed (and swapped) each time a reconnect completes after the first
+	lastConnectErr error                              // set on connect failure, cleared on success
+	replyQueue     string                             // message reply queue name
+	inflightReqs   map[string]chan<- message.Response // correlation id: response chan
+	inflightReqsM  sync.Mutex                         // protects inflightReqs
+	listeners      map[string]*tomb.Tomb              // service name: tomb
+	listenersM     sync.RWMutex                       // protects listeners
+	sockets        map[string]chan amqp.Delivery      // correlation id: stream frame chan, for open Sockets
+	socketsM       sync.Mutex                         // protects sockets
+	acceptC        chan transport.Socket              // Sockets opened by a peer, awaiting Accept
+
+	maxInflight    int                // cap on outstanding Send calls; see inflightSlots
+	inflightSlots  chan struct{}      // one buffered slot per allowed outstanding Send call
+	workerPoolSize int                // number of goroutines draining reqWorkC/rspWorkC
+	reqWorkC       chan reqJob        // bounded queue of request deliveries awaiting a worker
+	rspWorkC       chan amqp.Delivery // bounded queue of response deliveries awaiting a worker
+	dropCount      uint64             // deliveries dropped because reqWorkC/rspWorkC was full, plus stream opens nobody Accepted (atomic)
+}
+
+// reqJob pairs a request delivery with the listener channel it's destined
+// for, so a shared worker pool can service every Listen-ing service.
+type reqJob struct {
+	delivery amqp.Delivery
+	rc       chan<- message.Request
 }
 
 // run starts the asynchronous run-loop connecting to RabbitMQ
@@ -60,8 +89,9 @@ func (t *rabbitTransport) run() {
 		return conn
 	}
 	conn := initConn()
+	t.startWorkers()
 
-	t.tomb.Go(func() error {
+	t.Go(func() error {
 		defer func() {
 			t.killListeners()
 			conn.Close()
@@ -72,15 +102,18 @@ func (t *rabbitTransport) run() {
 		for {
 			log.Info(ctx, "[Typhon:RabbitTransport] Run loop connecting…")
 			select {
-			case <-t.tomb.Dying():
+			case <-t.Dying():
 				return nil
 
 			case <-conn.Init():
 				log.Info(ctx, "[Typhon:RabbitTransport] Run loop connected")
+				t.connM.Lock()
+				t.lastConnectErr = nil
+				t.connM.Unlock()
 				t.listenReplies()
 
 				select {
-				case <-t.tomb.Dying():
+				case <-t.Dying():
 					// Do not loop again
 					return nil
 				default:
@@ -92,35 +125,124 @@ func (t *rabbitTransport) run() {
 			case <-time.After(connectTimeout):
 				log.Critical(ctx, "[Typhon:RabbitTransport] Run loop timed out after %v waiting to connect",
 					connectTimeout)
+				t.connM.Lock()
+				t.lastConnectErr = ErrCouldntConnect
+				t.connM.Unlock()
 				return ErrCouldntConnect
 			}
 		}
 	})
 }
 
+// startWorkers launches the fixed-size pool of goroutines that drain
+// reqWorkC/rspWorkC, replacing the previous one-goroutine-per-delivery
+// behaviour so RabbitMQ's consumer prefetch and this pool size together
+// bound the memory a flood of deliveries can consume.
+func (t *rabbitTransport) startWorkers() {
+	for i := 0; i < t.workerPoolSize; i++ {
+		t.Go(func() error {
+			for {
+				select {
+				case job := <-t.reqWorkC:
+					t.handleReqDelivery(job.delivery, job.rc)
+				case delivery := <-t.rspWorkC:
+					t.handleRspDelivery(delivery)
+				case <-t.Dying():
+					return nil
+				}
+			}
+		})
+	}
+}
+
 // deliveryChan returns the name of a delivery channel for a given service
 func (t *rabbitTransport) deliveryChan(serviceName string) string {
 	return serviceName
 }
 
-func (t *rabbitTransport) Tomb() *tomb.Tomb {
-	return t.tomb
+// Start brings up the transport's connection-management goroutines; later
+// calls are no-ops. Callers that want to sequence bring-up explicitly
+// should call this directly; connection/Ready/OnReconnect also call it so a
+// transport used without an explicit Start still works.
+func (t *rabbitTransport) Start(ctx context.Context) error {
+	return t.BaseService.Start(ctx, t.run)
 }
 
 func (t *rabbitTransport) connection() *RabbitConnection {
-	t.runOnce.Do(t.run)
+	t.Start(context.Background())
 	t.connM.RLock()
 	defer t.connM.RUnlock()
 	return t.conn
 }
 
 func (t *rabbitTransport) Ready() <-chan struct{} {
-	t.runOnce.Do(t.run)
+	t.Start(context.Background())
 	t.connM.RLock()
 	defer t.connM.RUnlock()
 	return t.connReady
 }
 
+// OnReconnect returns a channel that's closed once the transport has
+// reconnected to RabbitMQ after losing its connection (i.e. a second or
+// later Ready, not the first). Unlike connReady, which is simply swapped
+// internally on every (re)connect attempt, this exists purely so callers
+// can observe that a reconnect happened.
+func (t *rabbitTransport) OnReconnect() <-chan struct{} {
+	t.Start(context.Background())
+	t.connM.RLock()
+	defer t.connM.RUnlock()
+	return t.reconnectC
+}
+
+// HealthStatus is a snapshot of a rabbitTransport's lifecycle and connection
+// state, returned by Health.
+type HealthStatus struct {
+	Running        bool            // true once Start has run and the tomb hasn't died
+	Connected      bool            // true once the reply queue consumer is up
+	LastConnectErr error           // the error from the most recent failed connect attempt, if any
+	Inflight       int             // number of Send calls awaiting a response
+	MaxInflight    int             // the cap Inflight is bounded by; see Send
+	Listeners      map[string]bool // service name: whether its listener tomb is still alive
+	QueueDepth     int             // deliveries currently buffered in reqWorkC/rspWorkC, awaiting a worker
+	QueueCapacity  int             // combined buffer size of reqWorkC/rspWorkC
+	Dropped        uint64          // deliveries dropped because the worker pool stayed saturated, plus stream opens nobody Accepted
+}
+
+// Health reports the transport's current lifecycle and connection state, for
+// callers wiring up a /healthz or /readyz endpoint.
+func (t *rabbitTransport) Health() HealthStatus {
+	t.connM.RLock()
+	connected := false
+	select {
+	case <-t.connReady:
+		connected = true
+	default:
+	}
+	lastErr := t.lastConnectErr
+	t.connM.RUnlock()
+
+	inflight := len(t.inflightSlots)
+
+	t.listenersM.RLock()
+	listeners := make(map[string]bool, len(t.listeners))
+	for name, tm := range t.listeners {
+		listeners[name] = tm.Alive()
+	}
+	t.listenersM.RUnlock()
+
+	return HealthStatus{
+		Running:        t.IsRunning(),
+		Connected:      connected,
+		LastConnectErr: lastErr,
+		Inflight:       inflight,
+		MaxInflight:    t.maxInflight,
+		Listeners:      listeners,
+		QueueDepth:     len(t.reqWorkC) + len(t.rspWorkC),
+		QueueCapacity:  cap(t.reqWorkC) + cap(t.rspWorkC),
+		Dropped:        atomic.LoadUint64(&t.dropCount),
+	}
+}
+
 func (t *rabbitTransport) killListeners() {
 	t.listenersM.RLock()
 	ts := make([]*tomb.Tomb, 0, len(t.listeners))
@@ -176,7 +298,7 @@ func (t *rabbitTransport) Listen(serviceName string, rc chan<- message.Request)
 		}()
 
 		select {
-		case <-t.tomb.Dying():
+		case <-t.Dying():
 			return nil
 		case <-tm.Dying():
 			return nil
@@ -198,7 +320,7 @@ func (t *rabbitTransport) Listen(serviceName string, rc chan<- message.Request)
 
 		for {
 			select {
-			case <-t.tomb.Dying():
+			case <-t.Dying():
 				return nil
 
 			case <-tm.Dying():
@@ -209,7 +331,7 @@ func (t *rabbitTransport) Listen(serviceName string, rc chan<- message.Request)
 					log.Warn(ctx, "[Typhon:RabbitTransport] Delivery channel closed; stopping listener %s", cn)
 					return nil
 				}
-				go t.handleReqDelivery(delivery, rc)
+				t.enqueueReqDelivery(delivery, rc)
 			}
 		}
 	})
@@ -229,7 +351,7 @@ func (t *rabbitTransport) Respond(req message.Request, rsp message.Response) err
 	select {
 	case <-t.Ready():
 		timeout.Stop()
-	case <-t.tomb.Dying():
+	case <-t.Dying():
 		return tomb.ErrDying
 	case <-timeout.C:
 		return transport.ErrTimeout
@@ -243,62 +365,182 @@ func (t *rabbitTransport) Respond(req message.Request, rsp message.Response) err
 	})
 }
 
-func (t *rabbitTransport) Send(req message.Request, _timeout time.Duration) (message.Response, error) {
+// Send publishes req and waits for a matching response, retrying per opts on
+// publish failure or a terrors 5xx response (see IsServerError). _timeout
+// bounds the whole call, not each attempt: once it elapses Send returns
+// transport.ErrTimeout and makes no further attempts, so an attempt that
+// times out is not retried. Each attempt publishes under a fresh correlation id (so a
+// slow first attempt's eventual reply can never be mistaken for a retry's),
+// all sharing one rspChan buffered to hold a reply from every attempt so a
+// late straggler never blocks handleRspDelivery.
+//
+// Send as a whole (not each attempt) reserves one slot from a fixed pool of
+// maxInflight, so a flood of Sends blocks (or, with the NonBlocking
+// CallOption, fails fast with ErrQueueFull) rather than growing inflightReqs
+// without bound; retrying within that single slot, rather than taking a
+// fresh one per attempt, is what stops a retrying call from exhausting the
+// pool against itself.
+//
+// If TYPHON_PROXY or TYPHON_PROXY_ADDRESS is set, req is routed to that
+// service instead of req.Service() and Retries is forced to 0: the proxy is
+// expected to own retry behaviour for requests passing through it.
+func (t *rabbitTransport) Send(req message.Request, _timeout time.Duration, opts ...transport.CallOption) (message.Response, error) {
 	ctx := context.Background()
-	id := req.Id()
-	if id == "" {
-		_uuid, err := uuid.NewV4()
-		if err != nil {
-			log.Error(ctx, "[Typhon:RabbitTransport] Failed to generate request uuid: %v", err)
-			return nil, err
-		}
-		req.SetId(_uuid.String())
+
+	var options transport.CallOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	routingKey := req.Service()
+	if proxy := proxyService(); proxy != "" {
+		routingKey = proxy
+		options.Retries = 0
+	}
+	backoff := defaultBackoff
+	if options.Backoff != nil {
+		backoff = options.Backoff
+	}
+	retry := defaultRetry
+	if options.Retry != nil {
+		retry = options.Retry
 	}
 
-	rspQueue := req.Id()
+	rspChan := make(chan message.Response, options.Retries+1)
+	ids := make([]string, 0, options.Retries+1)
 	defer func() {
 		t.inflightReqsM.Lock()
-		delete(t.inflightReqs, rspQueue)
+		for _, id := range ids {
+			delete(t.inflightReqs, id)
+		}
 		t.inflightReqsM.Unlock()
 	}()
-	rspChan := make(chan message.Response, 1)
-	t.inflightReqsM.Lock()
-	t.inflightReqs[rspQueue] = rspChan
-	t.inflightReqsM.Unlock()
 
 	timeout := time.NewTimer(_timeout)
 	defer timeout.Stop()
 
-	headers := req.Headers()
-	headers["Content-Encoding"] = "request"
-	headers["Service"] = req.Service()
-	headers["Endpoint"] = req.Endpoint()
-
-	select {
-	case <-t.Ready():
-	case <-timeout.C:
-		log.Warn(ctx, "[Typhon:RabbitTransport] Timed out after %v waiting for ready", _timeout)
-		return nil, transport.ErrTimeout
+	if options.NonBlocking {
+		select {
+		case t.inflightSlots <- struct{}{}:
+		default:
+			return nil, ErrQueueFull
+		}
+	} else {
+		select {
+		case t.inflightSlots <- struct{}{}:
+		case <-timeout.C:
+			log.Warn(ctx, "[Typhon:RabbitTransport] Timed out after %v waiting for a free in-flight slot", _timeout)
+			return nil, transport.ErrTimeout
+		}
 	}
+	defer func() { <-t.inflightSlots }()
 
-	if err := t.connection().Publish(Exchange, req.Service(), amqp.Publishing{
-		CorrelationId: req.Id(),
-		Timestamp:     time.Now().UTC(),
-		Body:          req.Payload(),
-		ReplyTo:       t.replyQueue,
-		Headers:       headersToTable(headers),
-	}); err != nil {
-		log.Error(ctx, "[Typhon:RabbitTransport] Failed to publish: %v", err)
-		return nil, err
+	var lastErr error
+	for attempt := 0; attempt <= options.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff(attempt)):
+			case <-timeout.C:
+				return nil, transport.ErrTimeout
+			}
+		}
+
+		if attempt > 0 || req.Id() == "" {
+			_uuid, err := uuid.NewV4()
+			if err != nil {
+				log.Error(ctx, "[Typhon:RabbitTransport] Failed to generate request uuid: %v", err)
+				return nil, err
+			}
+			req.SetId(_uuid.String())
+		}
+
+		t.inflightReqsM.Lock()
+		t.inflightReqs[req.Id()] = rspChan
+		t.inflightReqsM.Unlock()
+		ids = append(ids, req.Id())
+
+		headers := req.Headers()
+		headers["Content-Encoding"] = "request"
+		headers["Service"] = req.Service()
+		headers["Endpoint"] = req.Endpoint()
+
+		select {
+		case <-t.Ready():
+		case <-timeout.C:
+			log.Warn(ctx, "[Typhon:RabbitTransport] Timed out after %v waiting for ready", _timeout)
+			return nil, transport.ErrTimeout
+		}
+
+		if err := t.connection().Publish(Exchange, routingKey, amqp.Publishing{
+			CorrelationId: req.Id(),
+			Timestamp:     time.Now().UTC(),
+			Body:          req.Payload(),
+			ReplyTo:       t.replyQueue,
+			Headers:       headersToTable(headers),
+		}); err != nil {
+			log.Error(ctx, "[Typhon:RabbitTransport] Failed to publish: %v", err)
+			lastErr = err
+			if !retry(err, attempt) {
+				return nil, err
+			}
+			continue
+		}
+
+		select {
+		case rsp := <-rspChan:
+			if IsServerError(rsp) {
+				lastErr = terrors.InternalService("", "Server returned a 5xx response", nil)
+				if !retry(lastErr, attempt) {
+					return rsp, nil
+				}
+				continue
+			}
+			return rsp, nil
+
+		case <-timeout.C:
+			log.Warn(ctx, "[Typhon:RabbitTransport] Timed out after %v waiting for response to %s", _timeout, req.Id())
+			return nil, transport.ErrTimeout
+		}
 	}
 
-	select {
-	case rsp := <-rspChan:
-		return rsp, nil
-	case <-timeout.C:
-		log.Warn(ctx, "[Typhon:RabbitTransport] Timed out after %v waiting for response to %s", _timeout, req.Id())
-		return nil, transport.ErrTimeout
+	return nil, lastErr
+}
+
+// defaultBackoff is used when a Send's CallOptions don't specify one: a
+// linear 100ms per attempt.
+func defaultBackoff(attempt int) time.Duration {
+	return time.Duration(attempt) * 100 * time.Millisecond
+}
+
+// defaultRetry is used when a Send's CallOptions don't specify one: retry on
+// any error, which covers publish failures and the terrors 5xx responses Send
+// recognises via IsServerError. Send does not consult it on
+// transport.ErrTimeout, which ends the call.
+func defaultRetry(err error, attempt int) bool {
+	return err != nil
+}
+
+// IsServerError reports whether rsp carries a terrors 5xx-equivalent error,
+// by convention signalled via the "Status-Code" header set by the service
+// that produced it.
+func IsServerError(rsp message.Response) bool {
+	return strings.HasPrefix(rsp.Headers()["Status-Code"], "5")
+}
+
+const (
+	envProxy        = "TYPHON_PROXY"
+	envProxyAddress = "TYPHON_PROXY_ADDRESS"
+)
+
+// proxyService returns the service name Send should route requests to when
+// TYPHON_PROXY (or its legacy alias TYPHON_PROXY_ADDRESS) is set, asking
+// this service to route all outbound calls through a local proxy rather
+// than directly to the callee. It returns "" when neither is set.
+func proxyService() string {
+	if proxy := os.Getenv(envProxy); proxy != "" {
+		return proxy
 	}
+	return os.Getenv(envProxyAddress)
 }
 
 func (t *rabbitTransport) listenReplies() error {
@@ -317,15 +559,23 @@ func (t *rabbitTransport) listenReplies() error {
 	}
 
 	log.Debug(ctx, "[Typhon:RabbitTransport] Listening for replies on %s…", t.replyQueue)
-	t.connM.RLock()
+	t.connM.Lock()
 	readyC := t.connReady
-	t.connM.RUnlock()
 	select {
 	case <-readyC:
 		// Make sure not to close the channel if it's already closed
 	default:
 		close(readyC)
 	}
+	if t.connectedOnce {
+		// Signal OnReconnect only from the second successful connect
+		// onward; the first connect isn't a "reconnect" to anyone waiting
+		// on it.
+		close(t.reconnectC)
+		t.reconnectC = make(chan struct{})
+	}
+	t.connectedOnce = true
+	t.connM.Unlock()
 
 	for {
 		select {
@@ -334,9 +584,9 @@ func (t *rabbitTransport) listenReplies() error {
 				log.Warn(ctx, "[Typhon:RabbitTransport] Delivery channel %s closed", t.replyQueue)
 				return ErrDeliveriesClosed
 			}
-			go t.handleRspDelivery(delivery)
+			t.enqueueRspDelivery(delivery)
 
-		case <-t.tomb.Dying():
+		case <-t.Dying():
 			log.Info(ctx, "[Typhon:RabbitTransport] Reply listener terminating (tomb death)")
 			return tomb.ErrDying
 		}
@@ -360,6 +610,36 @@ func (t *rabbitTransport) deliveryToMessage(delivery amqp.Delivery, msg message.
 	}
 }
 
+// enqueueReqDelivery hands delivery to the worker pool for processing,
+// dropping it (and counting the drop in dropCount, surfaced via Health) if
+// reqWorkC stays full for chanSendTimeout rather than growing without bound.
+func (t *rabbitTransport) enqueueReqDelivery(delivery amqp.Delivery, rc chan<- message.Request) {
+	ctx := context.Background()
+	timeout := time.NewTimer(chanSendTimeout)
+	defer timeout.Stop()
+	select {
+	case t.reqWorkC <- reqJob{delivery: delivery, rc: rc}:
+	case <-timeout.C:
+		atomic.AddUint64(&t.dropCount, 1)
+		log.Error(ctx, "[Typhon:RabbitTransport] Dropped request delivery %s after %v: worker pool saturated",
+			t.logId(delivery), chanSendTimeout)
+	}
+}
+
+// enqueueRspDelivery is enqueueReqDelivery's counterpart for responses.
+func (t *rabbitTransport) enqueueRspDelivery(delivery amqp.Delivery) {
+	ctx := context.Background()
+	timeout := time.NewTimer(chanSendTimeout)
+	defer timeout.Stop()
+	select {
+	case t.rspWorkC <- delivery:
+	case <-timeout.C:
+		atomic.AddUint64(&t.dropCount, 1)
+		log.Error(ctx, "[Typhon:RabbitTransport] Dropped response delivery %s after %v: worker pool saturated",
+			t.logId(delivery), chanSendTimeout)
+	}
+}
+
 func (t *rabbitTransport) handleReqDelivery(delivery amqp.Delivery, reqChan chan<- message.Request) {
 	ctx := context.Background()
 	logId := t.logId(delivery)
@@ -378,6 +658,9 @@ func (t *rabbitTransport) handleReqDelivery(delivery amqp.Delivery, reqChan chan
 				logId, chanSendTimeout.String())
 		}
 
+	case "stream":
+		t.handleStreamOpenOrForward(delivery)
+
 	default:
 		log.Debug(ctx, "[Typhon:RabbitTransport] Cannot handle Content-Encoding \"%s\" as request for %s", enc, logId)
 	}
@@ -411,16 +694,57 @@ func (t *rabbitTransport) handleRspDelivery(delivery amqp.Delivery) {
 				logId, chanSendTimeout)
 		}
 
+	case "stream":
+		t.handleStreamForward(delivery)
+
 	default:
 		log.Error(ctx, "[Typhon:RabbitTransport] Cannot handle Content-Encoding \"%s\" as response for %s", enc, logId)
 	}
 }
 
-func NewTransport() transport.Transport {
+// rabbitTransport satisfies transport.Lifecycle, so callers that need
+// explicit Start/Stop or health/reconnect observability can type-assert for
+// it instead of importing this package directly.
+var _ transport.Lifecycle = (*rabbitTransport)(nil)
+
+func init() {
+	transport.Register("rabbit", NewTransport)
+}
+
+// NewTransport constructs a transport.Transport backed by RabbitMQ. The
+// underlying RabbitConnection configures its broker address from the
+// environment rather than from transport.Options; transport.MaxInflight and
+// transport.WorkerPoolSize are honoured (defaulting to defaultMaxInflight and
+// defaultWorkerPoolSize respectively) and the rest are accepted so "rabbit"
+// can be selected interchangeably with other backends via
+// transport.NewTransport.
+func NewTransport(opts ...transport.Option) transport.Transport {
+	var options transport.Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	maxInflight := options.MaxInflight
+	if maxInflight <= 0 {
+		maxInflight = defaultMaxInflight
+	}
+	workerPoolSize := options.WorkerPoolSize
+	if workerPoolSize <= 0 {
+		workerPoolSize = defaultWorkerPoolSize
+	}
+
 	return &rabbitTransport{
-		tomb:         new(tomb.Tomb),
-		inflightReqs: make(map[string]chan<- message.Response),
-		listeners:    make(map[string]*tomb.Tomb),
-		connReady:    make(chan struct{}),
-		replyQueue:   DirectReplyQueue}
+		inflightReqs:   make(map[string]chan<- message.Response),
+		listeners:      make(map[string]*tomb.Tomb),
+		connReady:      make(chan struct{}),
+		reconnectC:     make(chan struct{}),
+		replyQueue:     DirectReplyQueue,
+		sockets:        make(map[string]chan amqp.Delivery),
+		acceptC:        make(chan transport.Socket, acceptBacklog),
+		maxInflight:    maxInflight,
+		inflightSlots:  make(chan struct{}, maxInflight),
+		workerPoolSize: workerPoolSize,
+		reqWorkC:       make(chan reqJob, workQueueSize),
+		rspWorkC:       make(chan amqp.Delivery, workQueueSize),
+	}
 }
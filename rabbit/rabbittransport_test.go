@@ -0,0 +1,68 @@
+package rabbit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mondough/typhon/message"
+	"github.com/mondough/typhon/transport"
+)
+
+// requireReady skips t unless tr becomes Ready within a few seconds, so
+// these integration tests only run where an actual RabbitMQ broker is
+// reachable (there is no in-memory stand-in for the broker itself; see
+// memtransport for tests that don't need one).
+func requireReady(t *testing.T, tr transport.Transport) {
+	t.Helper()
+	select {
+	case <-tr.Ready():
+	case <-time.After(5 * time.Second):
+		t.Skip("no RabbitMQ broker reachable; skipping")
+	}
+}
+
+// TestSendHoldsOneSlotAcrossRetries guards against a regression where each
+// retry attempt acquired a fresh inflightSlots token without releasing the
+// previous attempt's, so a Send with Retries > 0 could need more than
+// MaxInflight tokens at once and deadlock against its own retries. With
+// MaxInflight(1) and a service that always answers 5xx (forcing every
+// attempt to retry), Send must still complete its retries well inside the
+// outer timeout instead of stalling until it elapses.
+func TestSendHoldsOneSlotAcrossRetries(t *testing.T) {
+	tr := NewTransport(transport.MaxInflight(1))
+	rt := tr.(*rabbitTransport)
+	defer rt.Stop()
+	requireReady(t, tr)
+
+	const service = "typhon-test-always-5xx"
+	rc := make(chan message.Request)
+	if err := tr.Listen(service, rc); err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer tr.StopListening(service)
+
+	go func() {
+		for req := range rc {
+			rsp := message.NewResponse()
+			rsp.SetId(req.Id())
+			rsp.SetHeader("Status-Code", "503")
+			tr.Respond(req, rsp)
+		}
+	}()
+
+	req := message.NewRequest()
+	req.SetService(service)
+
+	start := time.Now()
+	_, err := tr.Send(req, 2*time.Second, transport.Retries(2),
+		transport.WithBackoff(func(attempt int) time.Duration { return 10 * time.Millisecond }))
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("Send against an always-5xx service returned a nil error")
+	}
+	if elapsed >= 2*time.Second {
+		t.Errorf("Send took %v to give up, want well under the 2s outer timeout "+
+			"(retries likely starved waiting on a slot still held by a prior attempt)", elapsed)
+	}
+}
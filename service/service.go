@@ -0,0 +1,75 @@
+// Package service gives typhon's long-running components (Transport
+// backends, Broker implementations, …) a uniform Start/Stop/Wait lifecycle
+// to embed, instead of each reimplementing the same bookkeeping atop a bare
+// tomb.Tomb.
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/net/context"
+	"gopkg.in/tomb.v2"
+)
+
+// BaseService owns the tomb.Tomb backing a component's background work and
+// exposes it through Start/Stop/Wait/IsRunning, so callers can sequence
+// bring-up and teardown deterministically instead of relying on a
+// component's first use to trigger an implicit self-start.
+type BaseService struct {
+	startOnce sync.Once
+	started   int32 // set to 1 (atomically) once Start has run start
+	tomb      tomb.Tomb
+}
+
+// Start invokes start exactly once; start is expected to launch the
+// component's own background work (typically via Go) and return promptly,
+// not block for the component's lifetime. Later calls are no-ops, so Start
+// is safe to call from both an explicit caller and a component's own
+// implicit self-start path.
+func (b *BaseService) Start(ctx context.Context, start func()) error {
+	b.startOnce.Do(func() {
+		atomic.StoreInt32(&b.started, 1)
+		start()
+	})
+	return nil
+}
+
+// Go runs f in its own goroutine under the BaseService's tomb, so its
+// return value (and any panic) is observable via Wait.
+func (b *BaseService) Go(f func() error) {
+	b.tomb.Go(f)
+}
+
+// Dying returns a channel that's closed once Stop has been called (or the
+// tomb has died for any other reason), so background work can select on it
+// to stop early.
+func (b *BaseService) Dying() <-chan struct{} {
+	return b.tomb.Dying()
+}
+
+// Tomb returns the tomb.Tomb backing this BaseService, for components that
+// need to pass it on (e.g. as part of a public API their callers already
+// depend on).
+func (b *BaseService) Tomb() *tomb.Tomb {
+	return &b.tomb
+}
+
+// Stop signals the component's background work to exit and blocks until it
+// has.
+func (b *BaseService) Stop() error {
+	b.tomb.Kill(nil)
+	return b.tomb.Wait()
+}
+
+// Wait blocks until the component's background work has exited, for any
+// reason.
+func (b *BaseService) Wait() {
+	b.tomb.Wait()
+}
+
+// IsRunning reports whether the component has been Start-ed and has not yet
+// exited.
+func (b *BaseService) IsRunning() bool {
+	return atomic.LoadInt32(&b.started) == 1 && b.tomb.Alive()
+}
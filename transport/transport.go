@@ -0,0 +1,247 @@
+// Package transport defines typhon's pluggable messaging backend and a
+// registry of named implementations (analogous to database/sql's driver
+// registry, or go-micro's transport plugins). Services depend only on this
+// package; a backend such as rabbit, nats or memtransport is selected by
+// name at construction time, so swapping brokers never requires importing
+// broker-specific packages outside of main().
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/mondough/typhon/message"
+)
+
+var (
+	// ErrTimeout is returned when a Transport operation does not complete
+	// before its deadline.
+	ErrTimeout = errors.New("typhon/transport: timed out")
+	// ErrAlreadyListening is returned from Listen when a listener is already
+	// registered for the given service name.
+	ErrAlreadyListening = errors.New("typhon/transport: already listening")
+	// ErrSocketClosed is returned from a Socket's Send/Recv once it has been
+	// closed, by either end.
+	ErrSocketClosed = errors.New("typhon/transport: socket closed")
+)
+
+// Transport is implemented by each typhon messaging backend. It provides
+// point-to-point request/response delivery between services.
+type Transport interface {
+	// Listen registers rc to receive Requests addressed to serviceName. It
+	// blocks until listening has begun (or failed).
+	Listen(serviceName string, rc chan<- message.Request) error
+
+	// StopListening tears down a listener previously registered with
+	// Listen, returning false if none was registered for serviceName.
+	StopListening(serviceName string) bool
+
+	// Send delivers req and blocks until a matching Response is received or
+	// timeout elapses. opts configures per-call behaviour such as retries;
+	// a backend that has no notion of a given CallOption ignores it.
+	Send(req message.Request, timeout time.Duration, opts ...CallOption) (message.Response, error)
+
+	// Respond replies to req with rsp.
+	Respond(req message.Request, rsp message.Response) error
+
+	// Ready returns a channel that is closed once the Transport is able to
+	// send and receive messages.
+	Ready() <-chan struct{}
+
+	// Dial opens a Socket to service: a stream of many Messages sharing a
+	// single correlation id, for server-push or long-running RPCs that a
+	// single Send/Respond pair can't express.
+	Dial(service string) (Socket, error)
+
+	// Accept blocks until a peer Dials a Socket against a service this
+	// Transport is Listen-ing on, returning it once the handshake arrives.
+	Accept() (Socket, error)
+}
+
+// Lifecycle is implemented by Transport backends that bring up background
+// work explicitly (a connection, a run loop) rather than doing everything
+// inline, and so have something worth starting, stopping and reporting on.
+// Not every backend needs it (memtransport has no connection to bring up),
+// so it is kept separate from Transport rather than folded into it; callers
+// that want it type-assert:
+//
+//	if lc, ok := t.(transport.Lifecycle); ok {
+//		lc.Start(ctx)
+//	}
+type Lifecycle interface {
+	// Start brings up the backend's background work. Later calls are
+	// no-ops. A backend that also self-starts on first use (e.g. from
+	// Ready or Send) may call Start itself, so this is safe to call
+	// whether or not anything has used the Transport yet.
+	Start(ctx context.Context) error
+
+	// Stop signals the backend's background work to exit and blocks until
+	// it has.
+	Stop() error
+
+	// Wait blocks until the backend's background work has exited, for any
+	// reason.
+	Wait()
+
+	// IsRunning reports whether the backend has been Start-ed and has not
+	// yet exited.
+	IsRunning() bool
+
+	// OnReconnect returns a channel that's closed once the backend has
+	// reconnected after losing its connection (i.e. a second or later
+	// Ready, not the first).
+	OnReconnect() <-chan struct{}
+}
+
+// Socket is a bidirectional, ordered stream of Messages sharing a single
+// correlation id, opened with Dial (by the caller) or handed back from
+// Accept (by the service being called). Unlike Send/Respond, either end may
+// push many messages before either closes it.
+type Socket interface {
+	// Send writes msg to the peer.
+	Send(msg message.Message) error
+	// Recv blocks until the next message from the peer arrives, populating
+	// msg with it. It returns ErrSocketClosed once the peer has closed the
+	// Socket and no further messages remain.
+	Recv(msg message.Message) error
+	// Close sends a terminal frame to the peer and releases the Socket's
+	// resources.
+	Close() error
+}
+
+// Options carries backend-agnostic configuration understood by Transport
+// factories. A given backend is free to ignore Options that don't apply to
+// it.
+type Options struct {
+	// Addresses lists the broker/server addresses to connect to. Backends
+	// that have no notion of addresses (e.g. memtransport) ignore this.
+	Addresses []string
+	// MaxInflight caps the number of Send calls a backend will allow to be
+	// outstanding at once. Zero means the backend's default. Backends with
+	// no such bound (e.g. memtransport) ignore this.
+	MaxInflight int
+	// WorkerPoolSize caps the number of goroutines a backend uses to process
+	// incoming deliveries concurrently. Zero means the backend's default.
+	// Backends that process deliveries inline (e.g. memtransport) ignore
+	// this.
+	WorkerPoolSize int
+}
+
+// Option mutates Options; backend factories apply a chain of Options before
+// constructing a Transport.
+type Option func(*Options)
+
+// Addresses sets the broker/server addresses a Transport should connect to.
+func Addresses(addrs ...string) Option {
+	return func(o *Options) {
+		o.Addresses = addrs
+	}
+}
+
+// MaxInflight sets the cap on Send calls a backend allows to be outstanding
+// at once.
+func MaxInflight(n int) Option {
+	return func(o *Options) {
+		o.MaxInflight = n
+	}
+}
+
+// WorkerPoolSize sets the number of goroutines a backend uses to process
+// incoming deliveries concurrently.
+func WorkerPoolSize(n int) Option {
+	return func(o *Options) {
+		o.WorkerPoolSize = n
+	}
+}
+
+// Factory constructs a Transport from a set of Options. Backends register a
+// Factory under a name via Register.
+type Factory func(opts ...Option) Transport
+
+// CallOptions configures an individual Send call.
+type CallOptions struct {
+	// Retries is the number of times to retry a failed attempt, so Retries=2
+	// means up to 3 attempts in total. Zero (the default) means Send makes a
+	// single attempt.
+	Retries int
+	// Backoff computes how long to wait before the given attempt (counting
+	// from 1, the first retry). Nil means the backend's default.
+	Backoff func(attempt int) time.Duration
+	// Retry decides whether the attempt'th attempt, having failed with err,
+	// should be retried. Nil means the backend's default.
+	Retry func(err error, attempt int) bool
+	// NonBlocking, if true, makes Send fail fast with a backend-specific
+	// queue-full error instead of blocking when it is at its MaxInflight
+	// capacity. False (the default) means Send blocks until a slot frees up
+	// or timeout elapses.
+	NonBlocking bool
+}
+
+// CallOption mutates CallOptions; Send applies a chain of CallOptions before
+// making its first attempt.
+type CallOption func(*CallOptions)
+
+// Retries sets the number of times Send retries a failed attempt.
+func Retries(n int) CallOption {
+	return func(o *CallOptions) {
+		o.Retries = n
+	}
+}
+
+// WithBackoff sets the function Send uses to compute the delay before each
+// retry.
+func WithBackoff(backoff func(attempt int) time.Duration) CallOption {
+	return func(o *CallOptions) {
+		o.Backoff = backoff
+	}
+}
+
+// WithRetry sets the function Send uses to decide whether a failed attempt
+// should be retried.
+func WithRetry(retry func(err error, attempt int) bool) CallOption {
+	return func(o *CallOptions) {
+		o.Retry = retry
+	}
+}
+
+// NonBlocking makes Send fail fast with a backend-specific queue-full error
+// rather than blocking when the backend is at its MaxInflight capacity.
+func NonBlocking() CallOption {
+	return func(o *CallOptions) {
+		o.NonBlocking = true
+	}
+}
+
+var (
+	factoriesM sync.RWMutex
+	factories  = make(map[string]Factory)
+)
+
+// Register makes a Transport backend available under name, e.g. "rabbit" or
+// "nats". It is typically called from a backend package's init(), and
+// panics if name is already registered.
+func Register(name string, factory Factory) {
+	factoriesM.Lock()
+	defer factoriesM.Unlock()
+	if _, ok := factories[name]; ok {
+		panic(fmt.Sprintf("typhon/transport: backend %q already registered", name))
+	}
+	factories[name] = factory
+}
+
+// NewTransport constructs the Transport backend registered as name, applying
+// opts. It returns an error if no backend has been registered under that
+// name (usually because its package was never imported).
+func NewTransport(name string, opts ...Option) (Transport, error) {
+	factoriesM.RLock()
+	factory, ok := factories[name]
+	factoriesM.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("typhon/transport: no transport backend registered as %q", name)
+	}
+	return factory(opts...), nil
+}
@@ -0,0 +1,67 @@
+package transport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mondough/typhon/message"
+)
+
+// stubTransport is a minimal Transport used only to exercise the registry;
+// none of its methods are expected to be called by these tests.
+type stubTransport struct {
+	opts Options
+}
+
+func (s *stubTransport) Listen(serviceName string, rc chan<- message.Request) error { return nil }
+func (s *stubTransport) StopListening(serviceName string) bool                      { return false }
+func (s *stubTransport) Send(req message.Request, timeout time.Duration, opts ...CallOption) (message.Response, error) {
+	return nil, nil
+}
+func (s *stubTransport) Respond(req message.Request, rsp message.Response) error { return nil }
+func (s *stubTransport) Ready() <-chan struct{}                                  { return nil }
+func (s *stubTransport) Dial(service string) (Socket, error)                     { return nil, nil }
+func (s *stubTransport) Accept() (Socket, error)                                 { return nil, nil }
+
+func TestRegisterAndNewTransport(t *testing.T) {
+	const name = "stub-registry-test"
+	newStub := func(opts ...Option) Transport {
+		var options Options
+		for _, o := range opts {
+			o(&options)
+		}
+		return &stubTransport{opts: options}
+	}
+	Register(name, newStub)
+
+	tr, err := NewTransport(name, MaxInflight(7))
+	if err != nil {
+		t.Fatalf("NewTransport(%q) returned error: %v", name, err)
+	}
+	stub, ok := tr.(*stubTransport)
+	if !ok {
+		t.Fatalf("NewTransport(%q) returned %T, want *stubTransport", name, tr)
+	}
+	if stub.opts.MaxInflight != 7 {
+		t.Errorf("MaxInflight option not applied: got %d, want 7", stub.opts.MaxInflight)
+	}
+}
+
+func TestNewTransportUnregisteredName(t *testing.T) {
+	if _, err := NewTransport("no-such-backend"); err == nil {
+		t.Fatal("NewTransport with an unregistered name returned a nil error")
+	}
+}
+
+func TestRegisterPanicsOnDuplicate(t *testing.T) {
+	const name = "stub-registry-duplicate-test"
+	newStub := func(opts ...Option) Transport { return &stubTransport{} }
+	Register(name, newStub)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Register did not panic on a duplicate name")
+		}
+	}()
+	Register(name, newStub)
+}